@@ -0,0 +1,356 @@
+package svgparser
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matrix is a 2D affine transform, equivalent to the SVG
+// matrix(a, b, c, d, e, f) parameters:
+//
+//	[ a c e ]
+//	[ b d f ]
+//	[ 0 0 1 ]
+type Matrix struct {
+	A, B, C, D, E, F float64
+}
+
+// IdentityMatrix returns the identity transform.
+func IdentityMatrix() Matrix {
+	return Matrix{A: 1, D: 1}
+}
+
+// Multiply returns m concatenated with o, i.e. the matrix that applies o
+// first and then m, matching how SVG combines a transform="..." list
+// left to right.
+func (m Matrix) Multiply(o Matrix) Matrix {
+	return Matrix{
+		A: m.A*o.A + m.C*o.B,
+		B: m.B*o.A + m.D*o.B,
+		C: m.A*o.C + m.C*o.D,
+		D: m.B*o.C + m.D*o.D,
+		E: m.A*o.E + m.C*o.F + m.E,
+		F: m.B*o.E + m.D*o.F + m.F,
+	}
+}
+
+// transformCallRe matches one leading transform function call, with any
+// surrounding whitespace. Anchoring it to the start of the remaining
+// string (rather than searching anywhere) is what lets
+// splitTransformCalls detect garbage between or after calls instead of
+// silently skipping it.
+var transformCallRe = regexp.MustCompile(`^\s*(matrix|translate|scale|rotate|skewX|skewY)\(([^)]*)\)\s*`)
+
+// transformCall is one function call parsed out of a transform="..."
+// attribute value, e.g. {"translate", "10,20"}.
+type transformCall struct {
+	name string
+	args string
+}
+
+// splitTransformCalls parses v (already trimmed) into an ordered list of
+// transform function calls, requiring the calls to cover v end to end
+// (only whitespace allowed between them). A plain "search anywhere"
+// regexp would otherwise accept a string like "translate(1,2) bogus(3,4)"
+// and silently drop the unrecognized call.
+func splitTransformCalls(v string) ([]transformCall, error) {
+	var calls []transformCall
+	rest := v
+	for len(rest) > 0 {
+		m := transformCallRe.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("invalid transform %q", v)
+		}
+		calls = append(calls, transformCall{name: m[1], args: m[2]})
+		rest = rest[len(m[0]):]
+	}
+	return calls, nil
+}
+
+// ParseTransform parses an SVG transform="..." attribute value into a
+// single Matrix, combining matrix(), translate(), scale(), rotate(),
+// skewX() and skewY() calls in the order they appear.
+func ParseTransform(v string) (Matrix, error) {
+	result := IdentityMatrix()
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return result, nil
+	}
+
+	calls, err := splitTransformCalls(v)
+	if err != nil {
+		return result, fmt.Errorf("svgparser: %s", err.Error())
+	}
+
+	for _, c := range calls {
+		args, err := parseTransformArgs(c.args)
+		if err != nil {
+			return result, err
+		}
+		fn, err := transformMatrix(c.name, args)
+		if err != nil {
+			return result, err
+		}
+		result = result.Multiply(fn)
+	}
+	return result, nil
+}
+
+func parseTransformArgs(raw string) ([]float64, error) {
+	fields := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	args := make([]float64, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("svgparser: invalid transform argument %q", f)
+		}
+		args = append(args, n)
+	}
+	return args, nil
+}
+
+func transformMatrix(name string, args []float64) (Matrix, error) {
+	switch name {
+	case "matrix":
+		if len(args) != 6 {
+			return Matrix{}, fmt.Errorf("svgparser: matrix() requires 6 arguments, got %d", len(args))
+		}
+		return Matrix{A: args[0], B: args[1], C: args[2], D: args[3], E: args[4], F: args[5]}, nil
+
+	case "translate":
+		if len(args) == 1 {
+			args = []float64{args[0], 0}
+		}
+		if len(args) != 2 {
+			return Matrix{}, fmt.Errorf("svgparser: translate() requires 1 or 2 arguments, got %d", len(args))
+		}
+		return Matrix{A: 1, D: 1, E: args[0], F: args[1]}, nil
+
+	case "scale":
+		if len(args) == 1 {
+			args = []float64{args[0], args[0]}
+		}
+		if len(args) != 2 {
+			return Matrix{}, fmt.Errorf("svgparser: scale() requires 1 or 2 arguments, got %d", len(args))
+		}
+		return Matrix{A: args[0], D: args[1]}, nil
+
+	case "rotate":
+		if len(args) != 1 && len(args) != 3 {
+			return Matrix{}, fmt.Errorf("svgparser: rotate() requires 1 or 3 arguments, got %d", len(args))
+		}
+		theta := args[0] * math.Pi / 180
+		rot := Matrix{A: math.Cos(theta), B: math.Sin(theta), C: -math.Sin(theta), D: math.Cos(theta)}
+		if len(args) == 3 {
+			cx, cy := args[1], args[2]
+			toOrigin := Matrix{A: 1, D: 1, E: cx, F: cy}
+			fromOrigin := Matrix{A: 1, D: 1, E: -cx, F: -cy}
+			return toOrigin.Multiply(rot).Multiply(fromOrigin), nil
+		}
+		return rot, nil
+
+	case "skewX":
+		if len(args) != 1 {
+			return Matrix{}, fmt.Errorf("svgparser: skewX() requires 1 argument, got %d", len(args))
+		}
+		return Matrix{A: 1, D: 1, C: math.Tan(args[0] * math.Pi / 180)}, nil
+
+	case "skewY":
+		if len(args) != 1 {
+			return Matrix{}, fmt.Errorf("svgparser: skewY() requires 1 argument, got %d", len(args))
+		}
+		return Matrix{A: 1, D: 1, B: math.Tan(args[0] * math.Pi / 180)}, nil
+	}
+
+	return Matrix{}, fmt.Errorf("svgparser: unsupported transform function %q", name)
+}
+
+// inheritedAttrs lists the presentation attributes that inherit down the
+// element tree per the SVG 1.1 spec, unless a descendant sets its own
+// value.
+var inheritedAttrs = attrSet(
+	"fill", "fill-opacity", "fill-rule",
+	"stroke", "stroke-width", "stroke-opacity", "stroke-linecap", "stroke-linejoin", "stroke-dasharray",
+	"font-family", "font-size", "font-weight", "font-style",
+	"text-anchor", "color", "visibility", "cursor",
+)
+
+// ComputedAttribute returns the effective value of a presentation
+// attribute for e: e's own value if set, otherwise the nearest ancestor's
+// value if name inherits per the SVG 1.1 rules. The second return value
+// is false if neither e nor any ancestor sets it.
+func (e *Element) ComputedAttribute(name string) (string, bool) {
+	if v, ok := e.Attributes[name]; ok {
+		return v, true
+	}
+	if !inheritedAttrs[name] {
+		return "", false
+	}
+	for parent := e.Parent; parent != nil; parent = parent.Parent {
+		if v, ok := parent.Attributes[name]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// ComputedTransform multiplies the transform= attribute of e and all of
+// its ancestors, root first, into the single matrix that describes how e
+// is actually positioned.
+func (e *Element) ComputedTransform() Matrix {
+	var chain []*Element
+	for el := e; el != nil; el = el.Parent {
+		chain = append(chain, el)
+	}
+
+	result := IdentityMatrix()
+	for i := len(chain) - 1; i >= 0; i-- {
+		v, ok := chain[i].Attributes["transform"]
+		if !ok {
+			continue
+		}
+		m, err := ParseTransform(v)
+		if err != nil {
+			continue
+		}
+		result = result.Multiply(m)
+	}
+	return result
+}
+
+// UseCycleError is returned by ResolveUses when a chain of <use>
+// references loops back on itself.
+type UseCycleError struct {
+	ID string
+}
+
+func (err *UseCycleError) Error() string {
+	return fmt.Sprintf("svgparser: cyclic <use> reference to #%s", err.ID)
+}
+
+// ResolveUses returns a deep clone of root in which every <use
+// href="#id"> (or xlink:href) element is replaced by a <g> wrapping a
+// clone of the referenced subtree, with the use element's x, y, width,
+// height and transform attributes applied to the wrapping <g>. It
+// returns a *UseCycleError if a use chain is cyclic.
+func (root *Element) ResolveUses() (*Element, error) {
+	clone := root.clone(nil)
+	return resolveUsesIn(clone, clone, nil)
+}
+
+// clone deep-copies e, including its ordered Nodes (comments, CDATA,
+// processing instructions and RawXML alongside child elements), not just
+// the Children/Content convenience views. Every ElementNode's Element
+// pointer is remapped to the corresponding clone in c.Children, so the
+// two stay in sync.
+func (e *Element) clone(parent *Element) *Element {
+	c := &Element{
+		Name:    e.Name,
+		Content: e.Content,
+		Parent:  parent,
+	}
+	if e.Attributes != nil {
+		c.Attributes = make(map[string]string, len(e.Attributes))
+		for k, v := range e.Attributes {
+			c.Attributes[k] = v
+		}
+	}
+	if e.Attrs != nil {
+		c.Attrs = append([]Attr(nil), e.Attrs...)
+	}
+
+	if len(e.Nodes) > 0 {
+		c.Nodes = make([]Node, len(e.Nodes))
+		for i, n := range e.Nodes {
+			if n.Type == ElementNode && n.Element != nil {
+				n.Element = n.Element.clone(c)
+				c.Children = append(c.Children, n.Element)
+			}
+			c.Nodes[i] = n
+		}
+		return c
+	}
+
+	for _, child := range e.Children {
+		c.Children = append(c.Children, child.clone(c))
+	}
+	return c
+}
+
+// resolveUsesIn walks e (a subtree of clone root), replacing <use>
+// elements in place. stack holds the ids currently being expanded, to
+// detect cycles.
+func resolveUsesIn(root, e *Element, stack []string) (*Element, error) {
+	if e.Name == "use" {
+		href := e.Attributes["href"]
+		if !strings.HasPrefix(href, "#") {
+			return e, nil
+		}
+		id := href[1:]
+
+		for _, seen := range stack {
+			if seen == id {
+				return nil, &UseCycleError{ID: id}
+			}
+		}
+
+		target := root.FindID(id)
+		if target == nil {
+			return nil, fmt.Errorf("svgparser: <use> references unknown id %q", id)
+		}
+
+		resolvedTarget, err := resolveUsesIn(root, target.clone(nil), append(stack, id))
+		if err != nil {
+			return nil, err
+		}
+
+		group := &Element{
+			Name:       "g",
+			Attributes: make(map[string]string),
+			Parent:     e.Parent,
+		}
+		for _, k := range []string{"x", "y", "width", "height", "transform"} {
+			if v, ok := e.Attributes[k]; ok {
+				group.Attributes[k] = v
+			}
+		}
+		resolvedTarget.Parent = group
+		group.Children = []*Element{resolvedTarget}
+		return group, nil
+	}
+
+	resolvedChildren := make([]*Element, len(e.Children))
+	for i, child := range e.Children {
+		resolved, err := resolveUsesIn(root, child, stack)
+		if err != nil {
+			return nil, err
+		}
+		resolved.Parent = e
+		resolvedChildren[i] = resolved
+	}
+
+	// e.Nodes (if present) must be rewritten alongside e.Children: it is
+	// what MarshalXML actually walks, and its ElementNode entries point
+	// at the pre-resolve children by the same positional order they were
+	// appended in during clone().
+	if len(e.Nodes) > 0 {
+		newNodes := make([]Node, len(e.Nodes))
+		idx := 0
+		for i, n := range e.Nodes {
+			if n.Type == ElementNode {
+				n.Element = resolvedChildren[idx]
+				idx++
+			}
+			newNodes[i] = n
+		}
+		e.Nodes = newNodes
+	}
+
+	e.Children = resolvedChildren
+	return e, nil
+}
@@ -0,0 +1,105 @@
+package svgparser
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseTransformCombinesCalls(t *testing.T) {
+	m, err := ParseTransform("translate(10,20) scale(2)")
+	if err != nil {
+		t.Fatalf("ParseTransform: %v", err)
+	}
+	want := Matrix{A: 2, D: 2, E: 10, F: 20}
+	if m != want {
+		t.Errorf("ParseTransform(\"translate(10,20) scale(2)\") = %+v, want %+v", m, want)
+	}
+}
+
+func TestParseTransformRejectsGarbageBetweenCalls(t *testing.T) {
+	if _, err := ParseTransform("translate(1,2) bogus(3,4)"); err == nil {
+		t.Errorf("ParseTransform(\"translate(1,2) bogus(3,4)\") = nil error, want error")
+	}
+}
+
+func TestComputedAttributeInherits(t *testing.T) {
+	root := mustParse(t, `<svg fill="red"><g><rect fill="blue"/><circle/></g></svg>`)
+
+	g := root.Children[0]
+	rect := g.Children[0]
+	circle := g.Children[1]
+
+	if v, ok := rect.ComputedAttribute("fill"); !ok || v != "blue" {
+		t.Errorf("rect ComputedAttribute(fill) = %q, %v; want blue, true", v, ok)
+	}
+	if v, ok := circle.ComputedAttribute("fill"); !ok || v != "red" {
+		t.Errorf("circle ComputedAttribute(fill) = %q, %v; want red, true (inherited)", v, ok)
+	}
+	if _, ok := circle.ComputedAttribute("id"); ok {
+		t.Errorf("circle ComputedAttribute(id) = ok, want false (non-inheriting, unset)")
+	}
+}
+
+func TestComputedTransformMultipliesAncestors(t *testing.T) {
+	root := mustParse(t, `<svg transform="translate(10,0)"><g transform="scale(2)"><rect/></g></svg>`)
+
+	rect := root.Children[0].Children[0]
+	got := rect.ComputedTransform()
+	want := Matrix{A: 2, D: 2, E: 10, F: 0}
+	if got != want {
+		t.Errorf("ComputedTransform() = %+v, want %+v", got, want)
+	}
+}
+
+func TestResolveUsesReplacesUseAndPreservesComments(t *testing.T) {
+	src := `<svg><g id="foo"><!--keep me--><rect width="1" height="1"/></g><use href="#foo"/></svg>`
+
+	root := mustParse(t, src)
+	resolved, err := root.ResolveUses()
+	if err != nil {
+		t.Fatalf("ResolveUses: %v", err)
+	}
+
+	if len(resolved.Children) != 2 {
+		t.Fatalf("resolved root has %d children, want 2 (original <g> plus resolved <use>)", len(resolved.Children))
+	}
+	wrapper := resolved.Children[1]
+	if wrapper.Name != "g" {
+		t.Fatalf("resolved <use> replacement is <%s>, want <g>", wrapper.Name)
+	}
+
+	foundComment := false
+	for _, n := range wrapper.Children[0].Nodes {
+		if n.Type == CommentNode && n.Comment == "keep me" {
+			foundComment = true
+		}
+	}
+	if !foundComment {
+		t.Errorf("expected comment to survive ResolveUses, wrapper.Children[0].Nodes = %+v", wrapper.Children[0].Nodes)
+	}
+}
+
+func TestResolveUsesDetectsCycle(t *testing.T) {
+	src := `<svg><g id="a"><use href="#b"/></g><g id="b"><use href="#a"/></g></svg>`
+
+	root := mustParse(t, src)
+	_, err := root.ResolveUses()
+	if err == nil {
+		t.Fatalf("ResolveUses() = nil error, want *UseCycleError")
+	}
+	if _, ok := err.(*UseCycleError); !ok {
+		t.Errorf("ResolveUses() error type = %T, want *UseCycleError", err)
+	}
+}
+
+func TestComputedTransformIdentityWhenUnset(t *testing.T) {
+	root := mustParse(t, `<svg><rect/></svg>`)
+	got := root.Children[0].ComputedTransform()
+	want := IdentityMatrix()
+	if got != want {
+		t.Errorf("ComputedTransform() = %+v, want identity %+v", got, want)
+	}
+	if math.Abs(got.A-1) > 1e-9 {
+		t.Errorf("identity matrix A = %v, want 1", got.A)
+	}
+}
@@ -9,20 +9,73 @@ import (
 	"strings"
 )
 
-// ValidationError contains errors which have occured when parsing svg input.
-type ValidationError struct {
-	msg string
+// Attr is an XML attribute that remembers its original xml.Name (with
+// the namespace URI, or "xmlns" for a namespace declaration, in Space)
+// and its declaration order, unlike the Attributes map which only keeps
+// the local name.
+type Attr struct {
+	Name  xml.Name
+	Value string
 }
 
-func (err ValidationError) Error() string {
-	return err.msg
+// namespaceAttrName rewrites name for marshaling so that xmlns and
+// xmlns:prefix namespace declarations are written back as a literal
+// attribute name instead of being mistaken by encoding/xml for a
+// namespaced attribute that needs its own invented prefix binding.
+// encoding/xml's decoder reports xmlns:xlink="..." as
+// xml.Name{Space: "xmlns", Local: "xlink"}; passing that straight back to
+// the encoder makes it treat "xmlns" as a real namespace URI and invent a
+// bogus prefix for it (e.g. "_xmlns:xlink").
+func namespaceAttrName(name xml.Name) xml.Name {
+	if name.Space == "xmlns" {
+		return xml.Name{Local: "xmlns:" + name.Local}
+	}
+	return name
+}
+
+// NodeType identifies the concrete content held by a Node.
+type NodeType int
+
+// The kinds of content a Node can hold. Exactly one of them applies at a
+// time, matching Node.Type.
+const (
+	ElementNode NodeType = iota
+	CharDataNode
+	CDATANode
+	CommentNode
+	ProcInstNode
+	DirectiveNode
+	RawXMLNode
+)
+
+// Node is one ordered child of an Element: either a nested *Element or a
+// piece of non-element content (text, CDATA, a comment, a processing
+// instruction or a directive). It exists so Decode/MarshalXML can
+// round-trip that content in its original order instead of collapsing
+// everything into Children and Content. The output is not byte-identical
+// to the input: encoding/xml writes empty elements as a start/end tag
+// pair.
+//
+// Note: encoding/xml's tokenizer does not report whether character data
+// came from a CDATA section, so Decode always produces CharDataNode;
+// CDATANode is available for trees built or edited by hand.
+type Node struct {
+	Type      NodeType
+	Element   *Element
+	CharData  string
+	Comment   string
+	ProcInst  xml.ProcInst
+	Directive string
+	RawXML    *RawXML
 }
 
 // Element is a representation of an SVG element.
 type Element struct {
 	Name       string
 	Attributes map[string]string
+	Attrs      []Attr
 	Children   []*Element
+	Nodes      []Node
 	Parent     *Element
 	Content    string
 }
@@ -31,11 +84,14 @@ type Element struct {
 func NewElement(token xml.StartElement, parent *Element) *Element {
 	element := &Element{}
 	attributes := make(map[string]string)
+	attrs := make([]Attr, 0, len(token.Attr))
 	for _, attr := range token.Attr {
 		attributes[attr.Name.Local] = attr.Value
+		attrs = append(attrs, Attr{Name: attr.Name, Value: attr.Value})
 	}
 	element.Name = token.Name.Local
 	element.Attributes = attributes
+	element.Attrs = attrs
 	element.Parent = parent
 
 	return element
@@ -85,6 +141,13 @@ func DecodeFirst(decoder *xml.Decoder) (*Element, error) {
 
 // Decode decodes the child elements of element.
 func (e *Element) Decode(decoder *xml.Decoder) error {
+	return e.DecodeWithOptions(decoder, ParseOptions{})
+}
+
+// DecodeWithOptions is like Decode but, per opts, switches to capturing
+// opaque RawXML nodes for any child element whose namespace or name
+// matches instead of normalizing it into an Element.
+func (e *Element) DecodeWithOptions(decoder *xml.Decoder, opts ParseOptions) error {
 	for {
 		token, err := decoder.Token()
 		if token == nil && err == io.EOF {
@@ -95,24 +158,46 @@ func (e *Element) Decode(decoder *xml.Decoder) error {
 			return err
 		}
 
-		switch element := token.(type) {
+		switch t := token.(type) {
 		case xml.StartElement:
-			nextElement := NewElement(element, e)
-			err := nextElement.Decode(decoder)
-			if err != nil {
+			if opts.matchesRaw(t.Name) {
+				raw, err := captureRawXML(decoder, t)
+				if err != nil {
+					return err
+				}
+				e.Nodes = append(e.Nodes, Node{Type: RawXMLNode, RawXML: raw})
+				continue
+			}
+
+			nextElement := NewElement(t, e)
+			if err := nextElement.DecodeWithOptions(decoder, opts); err != nil {
 				return err
 			}
 
 			e.Children = append(e.Children, nextElement)
+			e.Nodes = append(e.Nodes, Node{Type: ElementNode, Element: nextElement})
 
 		case xml.CharData:
-			data := strings.TrimSpace(string(element))
+			data := strings.TrimSpace(string(t))
 			if data != "" {
-				e.Content = string(element)
+				e.Content = string(t)
 			}
+			e.Nodes = append(e.Nodes, Node{Type: CharDataNode, CharData: string(t)})
+
+		case xml.Comment:
+			e.Nodes = append(e.Nodes, Node{Type: CommentNode, Comment: string(t)})
+
+		case xml.ProcInst:
+			e.Nodes = append(e.Nodes, Node{Type: ProcInstNode, ProcInst: xml.ProcInst{
+				Target: t.Target,
+				Inst:   append([]byte(nil), t.Inst...),
+			}})
+
+		case xml.Directive:
+			e.Nodes = append(e.Nodes, Node{Type: DirectiveNode, Directive: string(append([]byte(nil), t...))})
 
 		case xml.EndElement:
-			if element.Name.Local == e.Name {
+			if t.Name.Local == e.Name {
 				return nil
 			}
 		}
@@ -120,6 +205,16 @@ func (e *Element) Decode(decoder *xml.Decoder) error {
 	return nil
 }
 
+// Document represents a fully parsed SVG document: the root element plus
+// any comments or processing instructions (e.g. <?xml-stylesheet?>) that
+// appeared before or after it, so that ParseDocument followed by
+// Marshal can round-trip the original prolog/epilog.
+type Document struct {
+	Prolog []Node
+	Root   *Element
+	Epilog []Node
+}
+
 // Parse creates an Element instance from an SVG input.
 func Parse(source io.Reader, validate bool) (*Element, error) {
 	raw, err := ioutil.ReadAll(source)
@@ -135,9 +230,75 @@ func Parse(source io.Reader, validate bool) (*Element, error) {
 	if err := element.Decode(decoder); err != nil && err != io.EOF {
 		return nil, err
 	}
+
+	if validate {
+		if err := element.Validate(); err != nil {
+			return element, err
+		}
+	}
+
 	return element, nil
 }
 
+// ParseDocument is like Parse but also preserves prolog/epilog-level
+// comments and processing instructions that sit outside the root
+// element.
+func ParseDocument(source io.Reader, validate bool) (*Document, error) {
+	raw, err := ioutil.ReadAll(source)
+	if err != nil {
+		return nil, err
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	doc := &Document{}
+	for {
+		token, err := decoder.Token()
+		if token == nil && err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			doc.Root = NewElement(t, nil)
+			if err := doc.Root.Decode(decoder); err != nil && err != io.EOF {
+				return nil, err
+			}
+
+		case xml.Comment:
+			doc.appendOutsideRoot(Node{Type: CommentNode, Comment: string(t)})
+
+		case xml.ProcInst:
+			doc.appendOutsideRoot(Node{Type: ProcInstNode, ProcInst: xml.ProcInst{
+				Target: t.Target,
+				Inst:   append([]byte(nil), t.Inst...),
+			}})
+
+		case xml.Directive:
+			doc.appendOutsideRoot(Node{Type: DirectiveNode, Directive: string(append([]byte(nil), t...))})
+		}
+	}
+
+	if validate && doc.Root != nil {
+		if err := doc.Root.Validate(); err != nil {
+			return doc, err
+		}
+	}
+
+	return doc, nil
+}
+
+func (doc *Document) appendOutsideRoot(node Node) {
+	if doc.Root == nil {
+		doc.Prolog = append(doc.Prolog, node)
+	} else {
+		doc.Epilog = append(doc.Epilog, node)
+	}
+}
+
 func (el Element) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 
 	openToken := xml.StartElement{
@@ -146,9 +307,14 @@ func (el Element) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		},
 	}
 
-	if len(el.Attributes) > 0 {
-		openToken.Attr = []xml.Attr{}
+	switch {
+	case len(el.Attrs) > 0:
+		openToken.Attr = make([]xml.Attr, len(el.Attrs))
+		for i, attr := range el.Attrs {
+			openToken.Attr[i] = xml.Attr{Name: namespaceAttrName(attr.Name), Value: attr.Value}
+		}
 
+	case len(el.Attributes) > 0:
 		for key, value := range el.Attributes {
 			openToken.Attr = append(openToken.Attr, xml.Attr{
 				Name: xml.Name{
@@ -163,21 +329,29 @@ func (el Element) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		return err
 	}
 
-	if len(el.Content) > 0 {
-		if err := e.EncodeToken(xml.CharData(el.Content)); err != nil {
-			return err
+	if len(el.Nodes) > 0 {
+		for _, node := range el.Nodes {
+			if err := node.encode(e); err != nil {
+				return err
+			}
 		}
-	}
-
-	for _, c := range el.Children {
-		if c != nil {
-			if err := c.MarshalXML(e, openToken); err != nil {
+	} else {
+		if len(el.Content) > 0 {
+			if err := e.EncodeToken(xml.CharData(el.Content)); err != nil {
 				return err
 			}
 		}
+
+		for _, c := range el.Children {
+			if c != nil {
+				if err := c.MarshalXML(e, openToken); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
-	closeToken := xml.EndElement{openToken.Name}
+	closeToken := xml.EndElement{Name: openToken.Name}
 
 	if err := e.EncodeToken(closeToken); err != nil {
 		return err
@@ -185,3 +359,54 @@ func (el Element) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 
 	return e.Flush()
 }
+
+// encode writes n's content to e, dispatching on n.Type.
+func (n Node) encode(e *xml.Encoder) error {
+	switch n.Type {
+	case ElementNode:
+		if n.Element == nil {
+			return nil
+		}
+		return n.Element.MarshalXML(e, xml.StartElement{})
+
+	case CharDataNode, CDATANode:
+		return e.EncodeToken(xml.CharData(n.CharData))
+
+	case CommentNode:
+		return e.EncodeToken(xml.Comment(n.Comment))
+
+	case ProcInstNode:
+		return e.EncodeToken(n.ProcInst)
+
+	case DirectiveNode:
+		return e.EncodeToken(xml.Directive(n.Directive))
+
+	case RawXMLNode:
+		if n.RawXML == nil {
+			return nil
+		}
+		return n.RawXML.MarshalXML(e, xml.StartElement{})
+	}
+	return nil
+}
+
+// MarshalXML writes the document's prolog, root element and epilog, in
+// that order.
+func (doc *Document) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	for _, node := range doc.Prolog {
+		if err := node.encode(e); err != nil {
+			return err
+		}
+	}
+	if doc.Root != nil {
+		if err := doc.Root.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	for _, node := range doc.Epilog {
+		if err := node.encode(e); err != nil {
+			return err
+		}
+	}
+	return e.Flush()
+}
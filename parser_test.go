@@ -0,0 +1,70 @@
+package svgparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestParseMarshalRoundTrip(t *testing.T) {
+	src := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="10" height="10">` +
+		`<!--a comment--><g id="layer1"><rect id="r1" fill="red"/></g></svg>`
+
+	el, err := Parse(strings.NewReader(src), false)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var out bytes.Buffer
+	enc := xml.NewEncoder(&out)
+	if err := el.MarshalXML(enc, xml.StartElement{}); err != nil {
+		t.Fatalf("MarshalXML: %v", err)
+	}
+
+	marshaled := out.String()
+	if !strings.Contains(marshaled, "<!--a comment-->") {
+		t.Errorf("expected comment to survive round trip, got %s", marshaled)
+	}
+	if strings.Contains(marshaled, "_xmlns") {
+		t.Errorf("marshaled output invented a bogus _xmlns prefix, got %s", marshaled)
+	}
+
+	reparsed, err := Parse(strings.NewReader(marshaled), false)
+	if err != nil {
+		t.Fatalf("re-parsing marshaled output: %v", err)
+	}
+	if reparsed.FindID("r1") == nil {
+		t.Errorf("expected rect#r1 to survive round trip")
+	}
+	if v, ok := reparsed.Attributes["xlink"]; !ok || v != "http://www.w3.org/1999/xlink" {
+		t.Errorf("reparsed xmlns:xlink declaration = %q, %v; want the original xlink namespace URI", v, ok)
+	}
+	if !isNamespaceDecl(reparsed, "xlink") {
+		t.Errorf("reparsed xlink attribute is no longer recognized as a namespace declaration")
+	}
+}
+
+func TestParseDocumentPreservesProlog(t *testing.T) {
+	src := `<?xml version="1.0"?><!--before--><svg><g/></svg><!--after-->`
+
+	doc, err := ParseDocument(strings.NewReader(src), false)
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if len(doc.Prolog) != 2 {
+		t.Fatalf("Prolog = %d nodes, want 2", len(doc.Prolog))
+	}
+	if len(doc.Epilog) != 1 {
+		t.Fatalf("Epilog = %d nodes, want 1", len(doc.Epilog))
+	}
+
+	var out bytes.Buffer
+	enc := xml.NewEncoder(&out)
+	if err := doc.MarshalXML(enc, xml.StartElement{}); err != nil {
+		t.Fatalf("MarshalXML: %v", err)
+	}
+	if !strings.Contains(out.String(), "<!--before-->") || !strings.Contains(out.String(), "<!--after-->") {
+		t.Errorf("expected prolog/epilog comments to survive, got %s", out.String())
+	}
+}
@@ -0,0 +1,390 @@
+package svgparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathError is returned when an XPath-style expression passed to
+// CompilePath or FindPath cannot be compiled.
+type PathError struct {
+	Expr string
+	Msg  string
+}
+
+func (err *PathError) Error() string {
+	return fmt.Sprintf("svgparser: invalid path %q: %s", err.Expr, err.Msg)
+}
+
+// axis identifies how a segment selects candidate elements relative to
+// its context node.
+type axis int
+
+const (
+	axisChild axis = iota
+	axisDescendantOrSelf
+	axisSelf
+	axisParent
+)
+
+// predicate is a single bracketed test within a path segment, e.g.
+// "[1]", "[@id]", "[@id='a']", "[text()='a']" or "[contains(text(),'a')]".
+type predicate struct {
+	position        int // 1-based; 0 means "not a position test"
+	attr            string
+	attrValue       string
+	hasAttrValue    bool
+	textEquals      string
+	hasTextEquals   bool
+	textContains    string
+	hasTextContains bool
+}
+
+// segment is one compiled step of a Path, e.g. "g[@id='layer1']".
+type segment struct {
+	axis       axis
+	name       string // "*" matches any element name
+	predicates []predicate
+}
+
+// Path is a compiled XPath-style expression that can be evaluated
+// against an Element tree. Compile a Path once with CompilePath to reuse
+// it across many trees; FindPath compiles and evaluates in one call.
+//
+// The supported subset mirrors the common case of etree-style path
+// engines: absolute ("/svg/g") and relative segments separated by "/"
+// (child) or "//" (descendant-or-self), an element-name or "*" node
+// test, "." and ".." axes, and predicates "[N]", "[@attr]",
+// "[@attr='v']", "[text()='v']" and "[contains(text(),'v')]".
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// CompilePath compiles expr into a reusable Path.
+func CompilePath(expr string) (*Path, error) {
+	if expr == "" {
+		return nil, &PathError{Expr: expr, Msg: "empty expression"}
+	}
+
+	p := &Path{expr: expr}
+	rest := expr
+	leadingDescendant := false
+	if strings.HasPrefix(rest, "//") {
+		leadingDescendant = true
+		rest = rest[2:]
+	} else if strings.HasPrefix(rest, "/") {
+		rest = rest[1:]
+	}
+
+	descendant := leadingDescendant
+	for len(rest) > 0 {
+		end := findSegmentEnd(rest)
+		raw := rest[:end]
+
+		seg, err := compileSegment(raw, descendant)
+		if err != nil {
+			return nil, &PathError{Expr: expr, Msg: err.Error()}
+		}
+		p.segments = append(p.segments, seg)
+
+		if end >= len(rest) {
+			break
+		}
+		rest = rest[end:]
+		descendant = strings.HasPrefix(rest, "//")
+		if descendant {
+			rest = rest[2:]
+		} else {
+			rest = rest[1:]
+		}
+	}
+
+	if len(p.segments) == 0 {
+		return nil, &PathError{Expr: expr, Msg: "no segments"}
+	}
+
+	return p, nil
+}
+
+// findSegmentEnd returns the index of the next unbracketed "/" in s, or
+// len(s) if there is none.
+func findSegmentEnd(s string) int {
+	depth := 0
+	var inQuote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '\'' || c == '"':
+			inQuote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '/' && depth == 0:
+			return i
+		}
+	}
+	return len(s)
+}
+
+func compileSegment(raw string, descendant bool) (segment, error) {
+	seg := segment{axis: axisChild}
+	if descendant {
+		seg.axis = axisDescendantOrSelf
+	}
+
+	name := raw
+	predStr := ""
+	if i := strings.IndexByte(raw, '['); i >= 0 {
+		name = raw[:i]
+		predStr = raw[i:]
+	}
+
+	switch name {
+	case ".":
+		seg.axis = axisSelf
+	case "..":
+		seg.axis = axisParent
+	case "":
+		return seg, fmt.Errorf("missing node test in %q", raw)
+	default:
+		seg.name = name
+	}
+
+	for len(predStr) > 0 {
+		if predStr[0] != '[' {
+			return seg, fmt.Errorf("expected '[' in %q", predStr)
+		}
+
+		depth := 0
+		var inQuote byte
+		closeAt := -1
+		for i := 0; i < len(predStr); i++ {
+			c := predStr[i]
+			switch {
+			case inQuote != 0:
+				if c == inQuote {
+					inQuote = 0
+				}
+			case c == '\'' || c == '"':
+				inQuote = c
+			case c == '[':
+				depth++
+			case c == ']':
+				depth--
+				if depth == 0 {
+					closeAt = i
+				}
+			}
+			if closeAt >= 0 {
+				break
+			}
+		}
+		if closeAt < 0 {
+			return seg, fmt.Errorf("unterminated predicate in %q", predStr)
+		}
+
+		pred, err := compilePredicate(predStr[1:closeAt])
+		if err != nil {
+			return seg, err
+		}
+		seg.predicates = append(seg.predicates, pred)
+		predStr = predStr[closeAt+1:]
+	}
+
+	return seg, nil
+}
+
+func compilePredicate(body string) (predicate, error) {
+	body = strings.TrimSpace(body)
+	var pred predicate
+
+	if n, err := strconv.Atoi(body); err == nil {
+		if n < 1 {
+			return pred, fmt.Errorf("position predicate must be >= 1, got %d", n)
+		}
+		pred.position = n
+		return pred, nil
+	}
+
+	if strings.HasPrefix(body, "@") {
+		rest := body[1:]
+		if eq := strings.IndexByte(rest, '='); eq >= 0 {
+			value, err := unquote(strings.TrimSpace(rest[eq+1:]))
+			if err != nil {
+				return pred, err
+			}
+			pred.attr = strings.TrimSpace(rest[:eq])
+			pred.attrValue = value
+			pred.hasAttrValue = true
+			return pred, nil
+		}
+		pred.attr = strings.TrimSpace(rest)
+		return pred, nil
+	}
+
+	if strings.HasPrefix(body, "text()") {
+		rest := strings.TrimSpace(body[len("text()"):])
+		if !strings.HasPrefix(rest, "=") {
+			return pred, fmt.Errorf("unsupported predicate %q", body)
+		}
+		value, err := unquote(strings.TrimSpace(rest[1:]))
+		if err != nil {
+			return pred, err
+		}
+		pred.textEquals = value
+		pred.hasTextEquals = true
+		return pred, nil
+	}
+
+	if strings.HasPrefix(body, "contains(text(),") && strings.HasSuffix(body, ")") {
+		inner := body[len("contains(text(),") : len(body)-1]
+		value, err := unquote(strings.TrimSpace(inner))
+		if err != nil {
+			return pred, err
+		}
+		pred.textContains = value
+		pred.hasTextContains = true
+		return pred, nil
+	}
+
+	return pred, fmt.Errorf("unsupported predicate %q", body)
+}
+
+func unquote(s string) (string, error) {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], nil
+	}
+	return "", fmt.Errorf("expected quoted string, got %q", s)
+}
+
+// FindPath compiles expr and evaluates it against e, treating e as the
+// context node, returning every matching element.
+func (e *Element) FindPath(expr string) ([]*Element, error) {
+	path, err := CompilePath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return path.Find(e), nil
+}
+
+// FindPathFirst is like FindPath but returns only the first match, or
+// nil if the expression matched nothing.
+func (e *Element) FindPathFirst(expr string) (*Element, error) {
+	elements, err := e.FindPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 {
+		return nil, nil
+	}
+	return elements[0], nil
+}
+
+// Find evaluates the compiled path against e, treating e as the context
+// node for relative expressions. An absolute expression (one starting
+// with "/" or "//") is evaluated from the top of e's tree instead.
+func (p *Path) Find(e *Element) []*Element {
+	if e == nil {
+		return nil
+	}
+
+	if strings.HasPrefix(p.expr, "/") {
+		// Start from a virtual document node whose only child is the
+		// top-level element, so "/svg" matches the root itself.
+		top := e
+		for top.Parent != nil {
+			top = top.Parent
+		}
+		e = &Element{Children: []*Element{top}}
+	}
+
+	current := []*Element{e}
+	for _, seg := range p.segments {
+		var next []*Element
+		for _, ctx := range current {
+			next = append(next, seg.eval(ctx)...)
+		}
+		current = next
+	}
+	return current
+}
+
+func (seg segment) eval(ctx *Element) []*Element {
+	var candidates []*Element
+	switch seg.axis {
+	case axisSelf:
+		candidates = []*Element{ctx}
+	case axisParent:
+		if ctx.Parent != nil {
+			candidates = []*Element{ctx.Parent}
+		}
+	case axisDescendantOrSelf:
+		candidates = collectDescendants(ctx)
+	default: // axisChild
+		candidates = append(candidates, ctx.Children...)
+	}
+
+	var named []*Element
+	for _, c := range candidates {
+		if seg.name != "" && seg.name != "*" && c.Name != seg.name {
+			continue
+		}
+		named = append(named, c)
+	}
+
+	if len(seg.predicates) == 0 {
+		return named
+	}
+
+	var matched []*Element
+	for i, c := range named {
+		if seg.matchesPredicates(c, i+1) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+func collectDescendants(e *Element) []*Element {
+	var all []*Element
+	for _, child := range e.Children {
+		all = append(all, child)
+		all = append(all, collectDescendants(child)...)
+	}
+	return all
+}
+
+func (seg segment) matchesPredicates(e *Element, position int) bool {
+	for _, pred := range seg.predicates {
+		switch {
+		case pred.position > 0:
+			if position != pred.position {
+				return false
+			}
+		case pred.hasAttrValue:
+			if v, ok := e.Attributes[pred.attr]; !ok || v != pred.attrValue {
+				return false
+			}
+		case pred.attr != "":
+			if _, ok := e.Attributes[pred.attr]; !ok {
+				return false
+			}
+		case pred.hasTextEquals:
+			if e.Content != pred.textEquals {
+				return false
+			}
+		case pred.hasTextContains:
+			if !strings.Contains(e.Content, pred.textContains) {
+				return false
+			}
+		}
+	}
+	return true
+}
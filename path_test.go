@@ -0,0 +1,81 @@
+package svgparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, src string) *Element {
+	t.Helper()
+	el, err := Parse(strings.NewReader(src), false)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return el
+}
+
+func TestFindPath(t *testing.T) {
+	root := mustParse(t, `<svg>
+		<g id="layer1" stroke="red">
+			<path id="p1" fill="#ff0000" d="M0 0"/>
+			<path id="p2" fill="#00ff00" d="M1 1"/>
+		</g>
+		<g id="layer2">
+			<circle r="5"/>
+			<circle r="15"/>
+		</g>
+	</svg>`)
+
+	cases := []struct {
+		expr string
+		want []string
+	}{
+		{"//g[@id='layer1']/path[@fill='#ff0000']", []string{"p1"}},
+		{"//path", []string{"p1", "p2"}},
+		{"//g[@id='layer2']/circle[2]", []string{""}},
+		{"//g[1]", []string{"layer1"}},
+	}
+
+	for _, c := range cases {
+		got, err := root.FindPath(c.expr)
+		if err != nil {
+			t.Fatalf("FindPath(%q): %v", c.expr, err)
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("FindPath(%q): got %d results, want %d", c.expr, len(got), len(c.want))
+		}
+		for i, el := range got {
+			if el.Attributes["id"] != c.want[i] {
+				t.Errorf("FindPath(%q)[%d] id = %q, want %q", c.expr, i, el.Attributes["id"], c.want[i])
+			}
+		}
+	}
+}
+
+func TestFindPathFirst(t *testing.T) {
+	root := mustParse(t, `<svg><g id="a"/><g id="b"/></svg>`)
+
+	first, err := root.FindPathFirst("//g")
+	if err != nil {
+		t.Fatalf("FindPathFirst: %v", err)
+	}
+	if first == nil || first.Attributes["id"] != "a" {
+		t.Fatalf("FindPathFirst = %v, want g#a", first)
+	}
+
+	none, err := root.FindPathFirst("//missing")
+	if err != nil {
+		t.Fatalf("FindPathFirst: %v", err)
+	}
+	if none != nil {
+		t.Fatalf("FindPathFirst(//missing) = %v, want nil", none)
+	}
+}
+
+func TestCompilePathRejectsGarbage(t *testing.T) {
+	for _, expr := range []string{"", "//g[@id='unterminated", "//g[2bad]"} {
+		if _, err := CompilePath(expr); err == nil {
+			t.Errorf("CompilePath(%q) = nil error, want error", expr)
+		}
+	}
+}
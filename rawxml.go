@@ -0,0 +1,153 @@
+package svgparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"golang.org/x/net/html/charset"
+	"io"
+	"io/ioutil"
+)
+
+// RawXML preserves an element, and everything inside it, as the token
+// stream it was decoded from: namespaces, attribute order and child
+// ordering that the normal decode path would otherwise normalize away.
+// Names are kept by namespace URI, not by prefix: encoding/xml's decoder
+// has already resolved prefixes, so MarshalXML writes each namespaced
+// element with an xmlns="..." declaration and lets encoding/xml choose
+// prefixes for namespaced attributes; the original prefixes are not
+// reproduced.
+// It is meant for foreign-namespace subtrees such as <foreignObject>
+// payloads or editor metadata (<sodipodi:namedview>,
+// <inkscape:path-effect>) that this package has no business interpreting.
+type RawXML struct {
+	Start  xml.StartElement
+	Tokens []xml.Token
+}
+
+// MarshalXML writes the captured tokens back out in their original order,
+// with names written as described on RawXML.
+func (raw RawXML) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(fixStartElementAttrs(raw.Start)); err != nil {
+		return err
+	}
+	for _, tok := range raw.Tokens {
+		if err := e.EncodeToken(fixTokenAttrs(tok)); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: raw.Start.Name})
+}
+
+// fixTokenAttrs applies fixStartElementAttrs to tok if it is a
+// StartElement, and returns every other token unchanged. raw.Tokens can
+// contain nested elements (e.g. a captured subtree several levels deep),
+// any of which may carry their own xmlns/xmlns:prefix declarations.
+func fixTokenAttrs(tok xml.Token) xml.Token {
+	start, ok := tok.(xml.StartElement)
+	if !ok {
+		return tok
+	}
+	return fixStartElementAttrs(start)
+}
+
+// fixStartElementAttrs rewrites start's attribute names via
+// namespaceAttrName so namespace declarations captured verbatim by
+// captureRawXML marshal back as literal attribute names rather than
+// triggering encoding/xml's namespace-prefix invention. A literal
+// xmlns="..." equal to start.Name.Space is dropped, because encoding/xml
+// already writes that declaration for a namespaced element and would
+// otherwise emit the attribute twice.
+func fixStartElementAttrs(start xml.StartElement) xml.StartElement {
+	if len(start.Attr) == 0 {
+		return start
+	}
+	fixed := make([]xml.Attr, 0, len(start.Attr))
+	for _, a := range start.Attr {
+		if start.Name.Space != "" && a.Name.Space == "" && a.Name.Local == "xmlns" && a.Value == start.Name.Space {
+			continue
+		}
+		fixed = append(fixed, xml.Attr{Name: namespaceAttrName(a.Name), Value: a.Value})
+	}
+	start.Attr = fixed
+	return start
+}
+
+// ParseOptions configures ParseWithOptions.
+type ParseOptions struct {
+	// RawNamespaces lists namespace URIs whose elements should be
+	// decoded as opaque RawXML instead of being normalized into
+	// Elements.
+	RawNamespaces []string
+	// RawElements lists local element names, regardless of namespace,
+	// that should be decoded as opaque RawXML.
+	RawElements []string
+}
+
+func (opts ParseOptions) matchesRaw(name xml.Name) bool {
+	for _, ns := range opts.RawNamespaces {
+		if name.Space == ns {
+			return true
+		}
+	}
+	for _, n := range opts.RawElements {
+		if name.Local == n {
+			return true
+		}
+	}
+	return false
+}
+
+// captureRawXML reads tokens from decoder until start's matching
+// EndElement, returning them as a RawXML. decoder.Token() has already
+// produced start; this only reads what's inside it.
+func captureRawXML(decoder *xml.Decoder, start xml.StartElement) (*RawXML, error) {
+	raw := &RawXML{Start: xml.CopyToken(start).(xml.StartElement)}
+
+	depth := 1
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				return raw, nil
+			}
+		}
+
+		raw.Tokens = append(raw.Tokens, xml.CopyToken(token))
+	}
+}
+
+// ParseWithOptions is like Parse but lets callers preserve arbitrary
+// foreign-namespace subtrees verbatim, via opts, instead of having them
+// normalized by the default decode path.
+func ParseWithOptions(source io.Reader, validate bool, opts ParseOptions) (*Element, error) {
+	raw, err := ioutil.ReadAll(source)
+	if err != nil {
+		return nil, err
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	element, err := DecodeFirst(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if err := element.DecodeWithOptions(decoder, opts); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if validate {
+		if err := element.Validate(); err != nil {
+			return element, err
+		}
+	}
+
+	return element, nil
+}
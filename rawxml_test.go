@@ -0,0 +1,73 @@
+package svgparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestParseWithOptionsCapturesRawNamespace(t *testing.T) {
+	src := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:sodipodi="http://sodipodi.sourceforge.net/DTD/sodipodi-0.0.dtd">` +
+		`<sodipodi:namedview id="base" showgrid="false"><sodipodi:child/></sodipodi:namedview>` +
+		`<rect width="1" height="1"/></svg>`
+
+	el, err := ParseWithOptions(strings.NewReader(src), false, ParseOptions{
+		RawNamespaces: []string{"http://sodipodi.sourceforge.net/DTD/sodipodi-0.0.dtd"},
+	})
+	if err != nil {
+		t.Fatalf("ParseWithOptions: %v", err)
+	}
+
+	if len(el.Children) != 1 || el.Children[0].Name != "rect" {
+		t.Fatalf("expected only <rect> to be decoded as a normal Element, got Children = %+v", el.Children)
+	}
+
+	var raw *RawXML
+	for _, n := range el.Nodes {
+		if n.Type == RawXMLNode {
+			raw = n.RawXML
+		}
+	}
+	if raw == nil {
+		t.Fatalf("expected a RawXMLNode for <sodipodi:namedview>, got Nodes = %+v", el.Nodes)
+	}
+	if raw.Start.Name.Local != "namedview" {
+		t.Errorf("RawXML.Start.Name.Local = %q, want namedview", raw.Start.Name.Local)
+	}
+
+	var out bytes.Buffer
+	enc := xml.NewEncoder(&out)
+	if err := raw.MarshalXML(enc, xml.StartElement{}); err != nil {
+		t.Fatalf("RawXML.MarshalXML: %v", err)
+	}
+	enc.Flush()
+	if !strings.Contains(out.String(), "<child") {
+		t.Errorf("expected nested foreign-namespace child to survive, got %s", out.String())
+	}
+
+	var fullOut bytes.Buffer
+	fullEnc := xml.NewEncoder(&fullOut)
+	if err := el.MarshalXML(fullEnc, xml.StartElement{}); err != nil {
+		t.Fatalf("MarshalXML: %v", err)
+	}
+	fullEnc.Flush()
+	if strings.Contains(fullOut.String(), "_xmlns") {
+		t.Errorf("marshaled output invented a bogus _xmlns prefix, got %s", fullOut.String())
+	}
+}
+
+func TestParseWithOptionsCapturesRawElementByName(t *testing.T) {
+	src := `<svg><inkscape:path-effect id="x"><inner/></inkscape:path-effect><g/></svg>`
+
+	el, err := ParseWithOptions(strings.NewReader(src), false, ParseOptions{
+		RawElements: []string{"path-effect"},
+	})
+	if err != nil {
+		t.Fatalf("ParseWithOptions: %v", err)
+	}
+
+	if len(el.Children) != 1 || el.Children[0].Name != "g" {
+		t.Fatalf("expected only <g> to be decoded as a normal Element, got Children = %+v", el.Children)
+	}
+}
@@ -0,0 +1,114 @@
+package svgparser
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// TransformFunc is invoked once per token while streaming an SVG document
+// through TransformSVG. parents holds the stack of currently open
+// elements (outermost first; a StartElement tok is not included, while
+// for an EndElement tok the element it closes is still the last entry),
+// so callbacks can
+// make decisions based on context, e.g. "am I inside <defs>?". parents is
+// a fresh slice on every call, safe to retain past the callback's return.
+// The returned tokens are written to the output in order: returning nil
+// drops tok, and returning more than one token lets a callback insert
+// siblings around it.
+type TransformFunc func(parents []xml.StartElement, tok xml.Token) []xml.Token
+
+// TransformSVG streams tokens from r through fn and writes the result to
+// w, without ever materializing an Element tree. CharData, Comment,
+// ProcInst and Directive tokens are passed to fn like any other token, so
+// a no-op fn writes back the same elements, attributes, namespaces, text,
+// comments and processing instructions. The output is not byte-identical:
+// encoding/xml writes each namespaced element with its own xmlns="..."
+// declaration instead of its original prefix, and writes empty elements
+// as a start/end tag pair. This enables filtering,
+// attribute rewriting (e.g. stripping inkscape:* attributes) and ID
+// renaming over multi-hundred-MB SVGs.
+func TransformSVG(r io.Reader, w io.Writer, fn TransformFunc) error {
+	decoder := xml.NewDecoder(r)
+	encoder := xml.NewEncoder(w)
+
+	var parents []xml.StartElement
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tok = xml.CopyToken(tok)
+
+		parents = pushParent(parents, tok)
+
+		for _, out := range fn(currentParents(parents, tok), tok) {
+			if err := encoder.EncodeToken(fixTokenAttrs(out)); err != nil {
+				return err
+			}
+		}
+
+		parents = popParent(parents, tok)
+	}
+
+	return encoder.Flush()
+}
+
+// WalkSVG is like TransformSVG but for read-only consumers: fn is
+// invoked for each token with the current parent stack, and no output is
+// produced.
+func WalkSVG(r io.Reader, fn func(parents []xml.StartElement, tok xml.Token) error) error {
+	decoder := xml.NewDecoder(r)
+
+	var parents []xml.StartElement
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tok = xml.CopyToken(tok)
+
+		parents = pushParent(parents, tok)
+
+		if err := fn(currentParents(parents, tok), tok); err != nil {
+			return err
+		}
+
+		parents = popParent(parents, tok)
+	}
+
+	return nil
+}
+
+// currentParents returns the parent stack as seen by tok itself: a
+// StartElement is not its own parent, so it is reported before being
+// pushed. The result is always a defensive copy: parents is a single
+// slice whose backing array keeps growing and being reused across the
+// whole walk, so handing it out directly would let a later sibling's push
+// silently overwrite a slice an earlier callback invocation retained.
+func currentParents(parents []xml.StartElement, tok xml.Token) []xml.StartElement {
+	view := parents
+	if _, ok := tok.(xml.StartElement); ok {
+		view = parents[:len(parents)-1]
+	}
+	return append([]xml.StartElement(nil), view...)
+}
+
+func pushParent(parents []xml.StartElement, tok xml.Token) []xml.StartElement {
+	if start, ok := tok.(xml.StartElement); ok {
+		return append(parents, start)
+	}
+	return parents
+}
+
+func popParent(parents []xml.StartElement, tok xml.Token) []xml.StartElement {
+	if end, ok := tok.(xml.EndElement); ok && len(parents) > 0 && parents[len(parents)-1].Name == end.Name {
+		return parents[:len(parents)-1]
+	}
+	return parents
+}
@@ -0,0 +1,120 @@
+package svgparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestTransformSVGRoundTrip(t *testing.T) {
+	src := `<svg><g><rect width="1" height="2"/></g></svg>`
+
+	var out bytes.Buffer
+	err := TransformSVG(strings.NewReader(src), &out, func(parents []xml.StartElement, tok xml.Token) []xml.Token {
+		return []xml.Token{tok}
+	})
+	if err != nil {
+		t.Fatalf("TransformSVG: %v", err)
+	}
+
+	got, err := Parse(strings.NewReader(out.String()), false)
+	if err != nil {
+		t.Fatalf("re-parsing transformed output: %v", err)
+	}
+	want, err := Parse(strings.NewReader(src), false)
+	if err != nil {
+		t.Fatalf("parsing source: %v", err)
+	}
+	if !got.Compare(want) {
+		t.Fatalf("round-tripped tree differs: got %+v, want %+v", got, want)
+	}
+}
+
+func TestTransformSVGDropsTokens(t *testing.T) {
+	src := `<svg><rect id="a"/><rect id="b"/></svg>`
+
+	var dropping bool
+	var out bytes.Buffer
+	err := TransformSVG(strings.NewReader(src), &out, func(parents []xml.StartElement, tok xml.Token) []xml.Token {
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "rect" {
+			for _, a := range start.Attr {
+				if a.Name.Local == "id" && a.Value == "b" {
+					dropping = true
+				}
+			}
+		}
+		if dropping {
+			if end, ok := tok.(xml.EndElement); ok && end.Name.Local == "rect" {
+				dropping = false
+			}
+			return nil
+		}
+		return []xml.Token{tok}
+	})
+	if err != nil {
+		t.Fatalf("TransformSVG: %v", err)
+	}
+
+	if strings.Contains(out.String(), `id="b"`) {
+		t.Errorf("expected rect#b to be dropped, got %s", out.String())
+	}
+	if !strings.Contains(out.String(), `id="a"`) {
+		t.Errorf("expected rect#a to survive, got %s", out.String())
+	}
+}
+
+func TestWalkSVGReportsParents(t *testing.T) {
+	src := `<svg><defs><rect/></defs></svg>`
+
+	var sawRectUnderDefs bool
+	err := WalkSVG(strings.NewReader(src), func(parents []xml.StartElement, tok xml.Token) error {
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "rect" {
+			return nil
+		}
+		if len(parents) == 2 && parents[1].Name.Local == "defs" {
+			sawRectUnderDefs = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkSVG: %v", err)
+	}
+	if !sawRectUnderDefs {
+		t.Errorf("expected WalkSVG to report <rect> nested under <defs>")
+	}
+}
+
+// TestWalkSVGParentsSurviveRetention guards against aliasing: a caller
+// that retains the parents slice handed to one callback invocation (e.g.
+// to build an element-to-ancestor-path map) must still see that call's
+// own parents afterwards, not whatever a later sibling's push overwrote
+// a shared backing array with.
+func TestWalkSVGParentsSurviveRetention(t *testing.T) {
+	src := `<svg><g id="g1"><a/></g><g id="g2"><b/></g></svg>`
+
+	var retained []xml.StartElement
+	err := WalkSVG(strings.NewReader(src), func(parents []xml.StartElement, tok xml.Token) error {
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "a" {
+			retained = parents
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkSVG: %v", err)
+	}
+
+	if len(retained) != 2 {
+		t.Fatalf("retained parents = %d elements, want 2 (svg, g)", len(retained))
+	}
+	var gID string
+	for _, a := range retained[1].Attr {
+		if a.Name.Local == "id" {
+			gID = a.Value
+		}
+	}
+	if gID != "g1" {
+		t.Errorf("retained parents[1] id = %q, want g1 (later sibling g2 must not overwrite it)", gID)
+	}
+}
@@ -0,0 +1,354 @@
+package svgparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes a single SVG structural validation failure.
+type FieldError struct {
+	Element   string
+	Attribute string
+	Line      int
+	Msg       string
+}
+
+func (fe FieldError) String() string {
+	if fe.Attribute != "" {
+		return fmt.Sprintf("<%s> attribute %q: %s", fe.Element, fe.Attribute, fe.Msg)
+	}
+	return fmt.Sprintf("<%s>: %s", fe.Element, fe.Msg)
+}
+
+// ValidationError aggregates every FieldError found while validating an
+// SVG document, rather than stopping at the first problem.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (err *ValidationError) Error() string {
+	parts := make([]string, len(err.Errors))
+	for i, fe := range err.Errors {
+		parts[i] = fe.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validate walks e, checking SVG 1.1 structural rules: the root must be
+// <svg>, every element and attribute must belong to the SVG element set,
+// required attributes must be present, numeric/length/color/transform
+// attribute values must parse, ids must be unique and every href/
+// xlink:href or url(#id) reference must resolve. It returns a
+// *ValidationError aggregating every problem found, or nil.
+func (e *Element) Validate() error {
+	v := &validator{ids: make(map[string]bool)}
+	v.collectIDs(e)
+	v.checkElement(e, true)
+	v.checkReferences(e)
+
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errors}
+}
+
+type validator struct {
+	ids    map[string]bool
+	errors []FieldError
+}
+
+func (v *validator) fail(element, attribute, msg string) {
+	v.errors = append(v.errors, FieldError{Element: element, Attribute: attribute, Msg: msg})
+}
+
+func (v *validator) collectIDs(e *Element) {
+	if id, ok := e.Attributes["id"]; ok {
+		if v.ids[id] {
+			v.fail(e.Name, "id", fmt.Sprintf("duplicate id %q", id))
+		}
+		v.ids[id] = true
+	}
+	for _, child := range e.Children {
+		v.collectIDs(child)
+	}
+}
+
+func (v *validator) checkElement(e *Element, isRoot bool) {
+	if isRoot && e.Name != "svg" {
+		v.fail(e.Name, "", "root element must be <svg>")
+	}
+
+	allowed, hasSpecific := svgElementAttrs[e.Name]
+	validElement := svgElements[e.Name]
+	if !validElement {
+		v.fail(e.Name, "", "not a valid SVG 1.1 element")
+	}
+
+	for attr, value := range e.Attributes {
+		if isNamespaceDecl(e, attr) {
+			continue
+		}
+		if validElement && !globalAttrs[attr] && !(hasSpecific && allowed[attr]) {
+			v.fail(e.Name, attr, "not a valid attribute for this element")
+		}
+		if checker, ok := attrSyntax[attr]; ok {
+			if err := checker(value); err != nil {
+				v.fail(e.Name, attr, err.Error())
+			}
+		}
+	}
+
+	for _, group := range requiredAttrs[e.Name] {
+		if !hasAnyAttr(e, group) {
+			v.fail(e.Name, "", fmt.Sprintf("missing required attribute %s", strings.Join(group, " or ")))
+		}
+	}
+
+	if isRoot && !hasAnyAttr(e, []string{"viewBox"}) &&
+		!(hasAnyAttr(e, []string{"width"}) && hasAnyAttr(e, []string{"height"})) {
+		v.fail(e.Name, "", "root <svg> requires viewBox or width and height")
+	}
+
+	for _, child := range e.Children {
+		v.checkElement(child, false)
+	}
+}
+
+func (v *validator) checkReferences(e *Element) {
+	for attr, value := range e.Attributes {
+		ref, ok := extractRef(attr, value)
+		if !ok {
+			continue
+		}
+		if !v.ids[ref] {
+			v.fail(e.Name, attr, fmt.Sprintf("references unknown id %q", ref))
+		}
+	}
+	for _, child := range e.Children {
+		v.checkReferences(child)
+	}
+}
+
+func extractRef(attr, value string) (string, bool) {
+	if attr == "href" && strings.HasPrefix(value, "#") {
+		return value[1:], true
+	}
+	if m := urlRefRe.FindStringSubmatch(value); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+// isNamespaceDecl reports whether attr is an xmlns or xmlns:prefix
+// namespace declaration on e, per e.Attrs' original xml.Name. These are
+// not presentation or structural attributes and should never be checked
+// against an element's attribute allow-list.
+func isNamespaceDecl(e *Element, attr string) bool {
+	if attr == "xmlns" {
+		return true
+	}
+	for _, a := range e.Attrs {
+		if a.Name.Local == attr && a.Name.Space == "xmlns" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyAttr(e *Element, names []string) bool {
+	for _, n := range names {
+		if _, ok := e.Attributes[n]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func attrSet(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+// svgElements lists the SVG 1.1 element names this package recognizes.
+// It is not exhaustive of the full spec, but covers the elements real
+// documents use.
+var svgElements = attrSet(
+	"svg", "g", "defs", "symbol", "use",
+	"path", "rect", "circle", "ellipse", "line", "polyline", "polygon",
+	"text", "tspan", "textPath",
+	"linearGradient", "radialGradient", "stop",
+	"clipPath", "mask", "pattern", "filter",
+	"image", "style", "title", "desc", "metadata",
+	"a", "switch", "marker", "foreignObject",
+)
+
+// svgElementAttrs lists the attributes specific to each known element,
+// in addition to globalAttrs which are allowed everywhere.
+var svgElementAttrs = map[string]map[string]bool{
+	"svg":            attrSet("width", "height", "viewBox", "xmlns", "version", "preserveAspectRatio"),
+	"g":              attrSet(),
+	"defs":           attrSet(),
+	"symbol":         attrSet("viewBox", "preserveAspectRatio", "x", "y", "width", "height"),
+	"use":            attrSet("href", "x", "y", "width", "height"),
+	"path":           attrSet("d", "pathLength"),
+	"rect":           attrSet("x", "y", "width", "height", "rx", "ry"),
+	"circle":         attrSet("cx", "cy", "r"),
+	"ellipse":        attrSet("cx", "cy", "rx", "ry"),
+	"line":           attrSet("x1", "y1", "x2", "y2"),
+	"polyline":       attrSet("points"),
+	"polygon":        attrSet("points"),
+	"text":           attrSet("x", "y", "dx", "dy", "rotate", "textLength", "lengthAdjust"),
+	"tspan":          attrSet("x", "y", "dx", "dy", "rotate", "textLength", "lengthAdjust"),
+	"textPath":       attrSet("href", "startOffset", "method", "spacing"),
+	"linearGradient": attrSet("x1", "y1", "x2", "y2", "gradientUnits", "gradientTransform", "href"),
+	"radialGradient": attrSet("cx", "cy", "r", "fx", "fy", "gradientUnits", "gradientTransform", "href"),
+	"stop":           attrSet("offset", "stop-color", "stop-opacity"),
+	"clipPath":       attrSet("clipPathUnits"),
+	"mask":           attrSet("x", "y", "width", "height", "maskUnits", "maskContentUnits"),
+	"pattern":        attrSet("x", "y", "width", "height", "patternUnits", "patternContentUnits", "patternTransform", "href"),
+	"filter":         attrSet("x", "y", "width", "height", "filterUnits", "primitiveUnits"),
+	"image":          attrSet("href", "x", "y", "width", "height", "preserveAspectRatio"),
+	"style":          attrSet("type"),
+	"title":          attrSet(),
+	"desc":           attrSet(),
+	"metadata":       attrSet(),
+	"a":              attrSet("href", "target"),
+	"switch":         attrSet(),
+	"marker":         attrSet("markerWidth", "markerHeight", "refX", "refY", "orient", "markerUnits", "viewBox"),
+	"foreignObject":  attrSet("x", "y", "width", "height"),
+}
+
+// globalAttrs lists attributes allowed on any known SVG element: the
+// core attributes and the common presentation properties.
+var globalAttrs = attrSet(
+	"id", "class", "style", "transform",
+	"fill", "fill-opacity", "fill-rule",
+	"stroke", "stroke-width", "stroke-opacity", "stroke-linecap", "stroke-linejoin", "stroke-dasharray",
+	"opacity", "font-family", "font-size", "font-weight", "text-anchor",
+	"clip-path", "mask", "filter", "visibility", "display",
+)
+
+// requiredAttrs lists, per element, the attribute groups that must have
+// at least one member present.
+var requiredAttrs = map[string][][]string{
+	"path":     {{"d"}},
+	"polygon":  {{"points"}},
+	"polyline": {{"points"}},
+	"circle":   {{"r"}},
+	"line":     {{"x1"}, {"y1"}, {"x2"}, {"y2"}},
+	"image":    {{"href"}},
+	"use":      {{"href"}},
+}
+
+// attrSyntax validates the value syntax of attributes that have a
+// well-defined grammar, independent of which element carries them.
+var attrSyntax = map[string]func(string) error{
+	"width":     validateLength,
+	"height":    validateLength,
+	"x":         validateLength,
+	"y":         validateLength,
+	"x1":        validateLength,
+	"y1":        validateLength,
+	"x2":        validateLength,
+	"y2":        validateLength,
+	"cx":        validateLength,
+	"cy":        validateLength,
+	"r":         validateLength,
+	"rx":        validateLength,
+	"ry":        validateLength,
+	"viewBox":   validateViewBox,
+	"points":    validatePoints,
+	"fill":      validateColor,
+	"stroke":    validateColor,
+	"transform": validateTransformSyntax,
+}
+
+var (
+	lengthRe   = regexp.MustCompile(`^-?[0-9]*\.?[0-9]+(px|pt|pc|cm|mm|in|em|ex|%)?$`)
+	colorHexRe = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	colorRgbRe = regexp.MustCompile(`^rgb\(\s*\d+%?\s*,\s*\d+%?\s*,\s*\d+%?\s*\)$`)
+	urlRefRe   = regexp.MustCompile(`^url\(#([^)]+)\)$`)
+)
+
+var namedColors = attrSet(
+	"none", "currentColor", "transparent",
+	"black", "white", "red", "green", "blue", "yellow", "orange", "purple",
+	"gray", "grey", "silver", "maroon", "olive", "lime", "teal", "navy",
+	"aqua", "fuchsia", "pink", "brown", "gold", "indigo", "violet",
+)
+
+func validateLength(v string) error {
+	if !lengthRe.MatchString(strings.TrimSpace(v)) {
+		return fmt.Errorf("invalid length %q", v)
+	}
+	return nil
+}
+
+func validateViewBox(v string) error {
+	fields := strings.Fields(strings.ReplaceAll(v, ",", " "))
+	if len(fields) != 4 {
+		return fmt.Errorf("viewBox must have 4 numbers, got %q", v)
+	}
+	for _, f := range fields {
+		if _, err := strconv.ParseFloat(f, 64); err != nil {
+			return fmt.Errorf("invalid number %q in viewBox", f)
+		}
+	}
+	return nil
+}
+
+func validatePoints(v string) error {
+	fields := strings.Fields(strings.ReplaceAll(v, ",", " "))
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return fmt.Errorf("points must contain an even number of coordinates, got %q", v)
+	}
+	for _, f := range fields {
+		if _, err := strconv.ParseFloat(f, 64); err != nil {
+			return fmt.Errorf("invalid coordinate %q in points", f)
+		}
+	}
+	return nil
+}
+
+func validateColor(v string) error {
+	v = strings.TrimSpace(v)
+	switch {
+	case namedColors[v]:
+		return nil
+	case colorHexRe.MatchString(v):
+		return nil
+	case colorRgbRe.MatchString(v):
+		return nil
+	case urlRefRe.MatchString(v):
+		return nil
+	default:
+		return fmt.Errorf("invalid color %q", v)
+	}
+}
+
+func validateTransformSyntax(v string) error {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil
+	}
+
+	calls, err := splitTransformCalls(v)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range calls {
+		args := strings.Fields(strings.ReplaceAll(c.args, ",", " "))
+		for _, a := range args {
+			if _, err := strconv.ParseFloat(a, 64); err != nil {
+				return fmt.Errorf("invalid argument %q in transform %q", a, v)
+			}
+		}
+	}
+	return nil
+}
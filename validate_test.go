@@ -0,0 +1,80 @@
+package svgparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAllowsXlinkNamespaceDecl(t *testing.T) {
+	src := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10">` +
+		`<use xlink:href="#a"/><rect id="a" width="1" height="1"/></svg>`
+
+	el, err := Parse(strings.NewReader(src), false)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if err := el.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestValidateRejectsUnknownAttrOnKnownElement(t *testing.T) {
+	src := `<svg viewBox="0 0 10 10"><text bogus-attr="x">hi</text></svg>`
+
+	el, err := Parse(strings.NewReader(src), false)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if err := el.Validate(); err == nil {
+		t.Fatalf("Validate() = nil, want error for <text bogus-attr>")
+	}
+}
+
+func TestValidateRejectsGarbageTransform(t *testing.T) {
+	src := `<svg viewBox="0 0 10 10"><g transform="translate(1,2) bogus(3,4)"/></svg>`
+
+	el, err := Parse(strings.NewReader(src), false)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if err := el.Validate(); err == nil {
+		t.Fatalf("Validate() = nil, want error for garbage interleaved with a valid transform call")
+	}
+}
+
+func TestValidateCatchesDuplicateIDsAndBadRefs(t *testing.T) {
+	src := `<svg viewBox="0 0 10 10">` +
+		`<rect id="a" width="1" height="1"/><rect id="a" width="1" height="1"/>` +
+		`<use href="#missing"/></svg>`
+
+	el, err := Parse(strings.NewReader(src), false)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	err = el.Validate()
+	if err == nil {
+		t.Fatalf("Validate() = nil, want error")
+	}
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Validate() error type = %T, want *ValidationError", err)
+	}
+	if len(ve.Errors) < 2 {
+		t.Fatalf("Validate() reported %d errors, want at least 2 (duplicate id, unknown ref)", len(ve.Errors))
+	}
+}
+
+func TestValidateAcceptsWellFormedDocument(t *testing.T) {
+	src := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 100">` +
+		`<g id="layer1" transform="translate(5,5) scale(2)">` +
+		`<rect x="0" y="0" width="10" height="10" fill="#ff0000"/>` +
+		`</g></svg>`
+
+	el, err := Parse(strings.NewReader(src), false)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if err := el.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil", err)
+	}
+}